@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// AvroSink writes records to an Avro Object Container File, the columnar
+// archival format warehouses such as BigQuery and ClickHouse import from
+// directly.
+type AvroSink struct {
+	file    *os.File
+	writer  *goavro.OCFWriter
+	columns []string
+}
+
+func newAvroSink(outputPath string, appendMode bool, columns []string) (*AvroSink, error) {
+	var file *os.File
+	var err error
+	if appendMode {
+		// goavro reads the existing OCF header and seeks to the tail when
+		// handed an *os.File that already has content, so just open for
+		// read/write rather than truncating.
+		file, err = os.OpenFile(outputPath, os.O_CREATE|os.O_RDWR, 0644)
+	} else {
+		file, err = os.Create(outputPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	writer, err := goavro.NewOCFWriter(goavro.OCFConfig{
+		W:      file,
+		Schema: avroRecordSchema(columns),
+	})
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &AvroSink{file: file, writer: writer, columns: columns}, nil
+}
+
+// WriteHeader is a no-op: the Avro schema embedded in the file already
+// describes the columns.
+func (s *AvroSink) WriteHeader(cols []string) error {
+	return nil
+}
+
+func (s *AvroSink) WriteRecord(r Record) error {
+	fields := map[string]interface{}{
+		"station_id": r.StationID,
+		"timestamp":  r.Timestamp,
+	}
+	for _, param := range s.columns {
+		fields[avroFieldName(param)] = avroOptionalDouble(r, param)
+	}
+	return s.writer.Append([]interface{}{fields})
+}
+
+func (s *AvroSink) Close() error {
+	return s.file.Close()
+}
+
+// avroOptionalDouble wraps r's named measurement for a Union(["null",
+// "double"]) field, so warehouses reading the OCF file get a native
+// numeric column instead of a string to re-parse.
+func avroOptionalDouble(r Record, name string) interface{} {
+	value, ok := r.Float64(name)
+	if !ok {
+		return nil
+	}
+	return goavro.Union("double", value)
+}
+
+// avroFieldName sanitizes a measurement name (e.g. "Wind Direction_Avg")
+// into a valid Avro field identifier.
+func avroFieldName(name string) string {
+	return strings.ReplaceAll(name, " ", "_")
+}
+
+// avroRecordSchema builds the Avro schema for one output row: the station
+// ID, timestamp, and one nullable double field per output column.
+func avroRecordSchema(columns []string) string {
+	var fields strings.Builder
+	fields.WriteString(`{"name":"station_id","type":"string"},`)
+	fields.WriteString(`{"name":"timestamp","type":"string"}`)
+	for _, param := range columns {
+		fields.WriteString(fmt.Sprintf(`,{"name":%q,"type":["null","double"],"default":null}`, avroFieldName(param)))
+	}
+	return fmt.Sprintf(`{"type":"record","name":"NesaRecord","fields":[%s]}`, fields.String())
+}
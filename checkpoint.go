@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/zeebo/blake3"
+)
+
+// headerHashSize is how many leading bytes of a file are hashed to detect
+// truncation or log rotation between runs.
+const headerHashSize = 4096
+
+// hashFileHeader returns the BLAKE3 hash of the first headerHashSize bytes
+// of file, regardless of the file's current seek position.
+func hashFileHeader(file *os.File) (string, error) {
+	buf := make([]byte, headerHashSize)
+	n, err := file.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	sum := blake3.Sum256(buf[:n])
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// headerMatches reports whether currentHash indicates filePath is still the
+// same file prevState was recorded from, rather than a rotated replacement.
+// Below headerHashSize bytes, the "header" hashed is the whole file, so a
+// plain append changes the hash just as much as a rotation would — the
+// hash can't tell them apart in that regime, so it's ignored there and
+// callers fall back to the size-only truncation check instead.
+func headerMatches(prevState FileState, currentHash string) bool {
+	if prevState.Size < headerHashSize {
+		return true
+	}
+	return prevState.HeaderHash == currentHash
+}
+
+// FileState records how much of an input file has already been processed,
+// so an incremental run only parses the bytes appended since the last one.
+type FileState struct {
+	Offset     int64     `json:"offset"`
+	Size       int64     `json:"size"`
+	ModTime    time.Time `json:"mtime"`
+	HeaderHash string    `json:"header_hash"`
+}
+
+// checkpointFile is the on-disk layout of a CheckpointStore: per-path
+// FileStates plus the (StationID, Timestamp) keys emitted by recent runs,
+// keyed by dedupeKey.String() since JSON object keys must be strings.
+type checkpointFile struct {
+	Files   map[string]FileState `json:"files"`
+	Emitted map[string]time.Time `json:"emitted"`
+}
+
+// CheckpointStore persists per-input-file read progress and recently
+// emitted record keys to a JSON state file (default
+// "<outputFile>.state"), so runs a few minutes apart can both skip bytes
+// they already processed and avoid re-emitting a record a previous run
+// already wrote — the case a reprocess-from-start (log rotation, a
+// changed header hash) would otherwise duplicate.
+type CheckpointStore struct {
+	path string
+
+	mu      sync.Mutex
+	states  map[string]FileState
+	emitted map[string]time.Time
+}
+
+// LoadCheckpointStore reads path if it exists, or returns an empty store
+// that will create it on Save.
+func LoadCheckpointStore(path string) (*CheckpointStore, error) {
+	store := &CheckpointStore{
+		path:    path,
+		states:  make(map[string]FileState),
+		emitted: make(map[string]time.Time),
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint file %s: %v", path, err)
+	}
+
+	var contents checkpointFile
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("cannot parse checkpoint file %s: %v", path, err)
+	}
+	if contents.Files != nil {
+		store.states = contents.Files
+	}
+	if contents.Emitted != nil {
+		store.emitted = contents.Emitted
+	}
+	return store, nil
+}
+
+// Get returns the last recorded state for path, if any.
+func (s *CheckpointStore) Get(path string) (FileState, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	state, ok := s.states[path]
+	return state, ok
+}
+
+// Set records the state to resume path from on the next run.
+func (s *CheckpointStore) Set(path string, state FileState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[path] = state
+}
+
+// WasRecentlyEmitted reports whether key was written to the output sink by
+// any run within window of now. This is what makes -dedupe-window actually
+// guard across runs: a single run's in-memory "seen" set starts out empty
+// every time, so without this, reprocessing a file from the start (log
+// rotation, a changed header hash) would re-emit rows a previous run
+// already wrote.
+func (s *CheckpointStore) WasRecentlyEmitted(key dedupeKey, window time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	at, ok := s.emitted[key.String()]
+	if !ok {
+		return false
+	}
+	return time.Since(at) < window
+}
+
+// MarkEmitted records that key was just written to the output sink.
+func (s *CheckpointStore) MarkEmitted(key dedupeKey, at time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.emitted == nil {
+		s.emitted = make(map[string]time.Time)
+	}
+	s.emitted[key.String()] = at
+}
+
+// PruneEmitted drops emitted entries older than window, so the state file
+// doesn't grow without bound across a long-running cron schedule.
+func (s *CheckpointStore) PruneEmitted(window time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if window <= 0 {
+		return
+	}
+	horizon := time.Now().Add(-window)
+	for key, at := range s.emitted {
+		if at.Before(horizon) {
+			delete(s.emitted, key)
+		}
+	}
+}
+
+// Save writes the store back to its state file.
+func (s *CheckpointStore) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := json.MarshalIndent(checkpointFile{Files: s.states, Emitted: s.emitted}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode checkpoint file %s: %v", s.path, err)
+	}
+	if err := os.WriteFile(s.path, data, 0644); err != nil {
+		return fmt.Errorf("cannot write checkpoint file %s: %v", s.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHeaderMatchesIgnoresHashBelowThreshold(t *testing.T) {
+	// A file smaller than headerHashSize hashes its whole content, so any
+	// append changes the hash even though nothing rotated. headerMatches
+	// must treat that as a match and leave truncation detection to the
+	// caller's size check.
+	prev := FileState{Size: 100, HeaderHash: "aaa"}
+	if !headerMatches(prev, "bbb") {
+		t.Fatalf("headerMatches should ignore the hash below headerHashSize")
+	}
+}
+
+func TestHeaderMatchesComparesHashAboveThreshold(t *testing.T) {
+	prev := FileState{Size: headerHashSize + 1, HeaderHash: "aaa"}
+	if headerMatches(prev, "bbb") {
+		t.Fatalf("headerMatches should compare the hash once the file has reached headerHashSize")
+	}
+	if !headerMatches(prev, "aaa") {
+		t.Fatalf("headerMatches should match an unchanged hash")
+	}
+}
+
+func TestCheckpointStoreEmittedRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	store, err := LoadCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpointStore: %v", err)
+	}
+
+	key := dedupeKey{StationID: "1", Timestamp: "2024-01-01T10:00:00"}
+	if store.WasRecentlyEmitted(key, time.Hour) {
+		t.Fatalf("a fresh store should have no emitted keys")
+	}
+
+	store.MarkEmitted(key, time.Now())
+	if !store.WasRecentlyEmitted(key, time.Hour) {
+		t.Fatalf("expected key to be recently emitted")
+	}
+
+	if err := store.Save(); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// A fresh process (e.g. the next cron run) must see the same emitted
+	// key after reloading the state file from disk.
+	reloaded, err := LoadCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpointStore (reload): %v", err)
+	}
+	if !reloaded.WasRecentlyEmitted(key, time.Hour) {
+		t.Fatalf("expected emitted key to survive a save/load round trip")
+	}
+}
+
+func TestCheckpointStorePruneEmitted(t *testing.T) {
+	store, err := LoadCheckpointStore(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("LoadCheckpointStore: %v", err)
+	}
+
+	key := dedupeKey{StationID: "1", Timestamp: "2024-01-01T10:00:00"}
+	store.MarkEmitted(key, time.Now().Add(-2*time.Hour))
+	store.PruneEmitted(time.Hour)
+
+	if store.WasRecentlyEmitted(key, time.Hour) {
+		t.Fatalf("expected a stale emitted key to be pruned")
+	}
+}
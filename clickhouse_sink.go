@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// clickHouseBatchSize caps how many rows accumulate before the sink issues
+// an INSERT, trading a little memory for far fewer HTTP round trips.
+const clickHouseBatchSize = 1000
+
+// ClickHouseSink batches rows as newline-delimited JSON and inserts them
+// into ClickHouse over its native HTTP interface using the JSONEachRow
+// input format.
+type ClickHouseSink struct {
+	addr   string
+	table  string
+	client *http.Client
+	batch  []clickHouseRecord
+}
+
+// clickHouseRecord is the JSONEachRow payload for one row. Values holds
+// native float64s rather than strings so ClickHouse stores them in a
+// numeric column type instead of having to re-parse text on every query.
+type clickHouseRecord struct {
+	StationID string             `json:"station_id"`
+	Timestamp string             `json:"timestamp"`
+	Values    map[string]float64 `json:"values"`
+}
+
+func newClickHouseSink(addr, table string) (*ClickHouseSink, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("clickhouse sink requires -clickhouse-addr")
+	}
+	if table == "" {
+		table = "nesacsv"
+	}
+	return &ClickHouseSink{addr: addr, table: table, client: &http.Client{}}, nil
+}
+
+// WriteHeader is a no-op: ClickHouse resolves columns from the table schema.
+func (s *ClickHouseSink) WriteHeader(cols []string) error {
+	return nil
+}
+
+func (s *ClickHouseSink) WriteRecord(r Record) error {
+	values := make(map[string]float64, len(r.Values))
+	for name := range r.Values {
+		if value, ok := r.Float64(name); ok {
+			values[name] = value
+		}
+	}
+
+	s.batch = append(s.batch, clickHouseRecord{StationID: r.StationID, Timestamp: r.Timestamp, Values: values})
+	if len(s.batch) >= clickHouseBatchSize {
+		return s.flush()
+	}
+	return nil
+}
+
+func (s *ClickHouseSink) Close() error {
+	return s.flush()
+}
+
+// flush posts the current batch as INSERT INTO <table> FORMAT JSONEachRow.
+func (s *ClickHouseSink) flush() error {
+	if len(s.batch) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	for _, record := range s.batch {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("cannot encode row for clickhouse: %v", err)
+		}
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s FORMAT JSONEachRow", s.table)
+	endpoint := fmt.Sprintf("%s/?query=%s", s.addr, url.QueryEscape(query))
+
+	resp, err := s.client.Post(endpoint, "application/json", &body)
+	if err != nil {
+		return fmt.Errorf("clickhouse insert failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("clickhouse insert failed: status %s", resp.Status)
+	}
+
+	// Only drop the batch once ClickHouse has confirmed the insert, so a
+	// transient HTTP/network failure leaves the rows queued for retry on
+	// the next WriteRecord/Close instead of silently discarding them.
+	s.batch = s.batch[:0]
+	return nil
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Processing describes one processing ID under a measurement: the output
+// column name it maps to, and how to convert the logger's raw value into
+// that column's engineering units (raw*scale + offset).
+type Processing struct {
+	ID     string  `yaml:"id"`
+	Name   string  `yaml:"name"`
+	Unit   string  `yaml:"unit,omitempty"`
+	Scale  float64 `yaml:"scale"`
+	Offset float64 `yaml:"offset,omitempty"`
+}
+
+// Measurement groups the processings recorded under one measurement ID.
+type Measurement struct {
+	ID          string       `yaml:"id"`
+	Processings []Processing `yaml:"processings"`
+}
+
+// Schema is the measurement/processing ID mapping loaded from a -mapping
+// YAML file, replacing what used to be the hardcoded measurementMap and
+// requiredMeasurements globals.
+type Schema struct {
+	Measurements  []Measurement `yaml:"measurements"`
+	Required      []string      `yaml:"required"`
+	OutputColumns []string      `yaml:"output_columns,omitempty"`
+
+	processings map[string]map[string]Processing
+}
+
+// LoadSchema reads and indexes a mapping file shaped like:
+//
+//	measurements:
+//	  - id: 1
+//	    processings:
+//	      - {id: 2, name: Temperature_Avg, unit: C, scale: 0.1}
+//	required: [Temperature_Avg, ...]
+//	output_columns: [...]   # optional, defaults to required
+func LoadSchema(path string) (*Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read mapping file %s: %v", path, err)
+	}
+
+	var schema Schema
+	if err := yaml.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("cannot parse mapping file %s: %v", path, err)
+	}
+	schema.index()
+	return &schema, nil
+}
+
+// defaultSchema recreates the tool's original, hardcoded measurement
+// mapping as a Schema with an identity scale, so running without -mapping
+// behaves exactly as before.
+func defaultSchema() *Schema {
+	schema := &Schema{
+		Measurements: []Measurement{
+			{ID: "1", Processings: []Processing{
+				{ID: "2", Name: "Temperature_Avg", Scale: 1},
+				{ID: "3", Name: "Temperature_Min", Scale: 1},
+				{ID: "4", Name: "Temperature_Max", Scale: 1},
+			}},
+			{ID: "2", Processings: []Processing{
+				{ID: "2", Name: "Humidity_Avg", Scale: 1},
+				{ID: "3", Name: "Humidity_Min", Scale: 1},
+				{ID: "4", Name: "Humidity_Max", Scale: 1},
+			}},
+			{ID: "9", Processings: []Processing{
+				{ID: "2", Name: "Windspeed_Avg", Scale: 1},
+				{ID: "3", Name: "Windspeed_Min", Scale: 1},
+				{ID: "4", Name: "Windspeed_Max", Scale: 1},
+			}},
+			{ID: "4", Processings: []Processing{
+				{ID: "2", Name: "Wind Direction_Avg", Scale: 1},
+				{ID: "3", Name: "Wind Direction_Min", Scale: 1},
+				{ID: "4", Name: "Wind Direction_Max", Scale: 1},
+			}},
+			{ID: "13", Processings: []Processing{
+				{ID: "2", Name: "Pressure_Avg", Scale: 1},
+				{ID: "3", Name: "Pressure_Min", Scale: 1},
+				{ID: "4", Name: "Pressure_Max", Scale: 1},
+			}},
+			{ID: "10", Processings: []Processing{
+				{ID: "7", Name: "Rainfall_Acc", Scale: 1},
+			}},
+			{ID: "51", Processings: []Processing{{ID: "2", Name: "Soiltemperature10_Avg", Scale: 1}}},
+			{ID: "101", Processings: []Processing{{ID: "2", Name: "Soiltemperature20_Avg", Scale: 1}}},
+			{ID: "151", Processings: []Processing{{ID: "2", Name: "Soiltemperature50_Avg", Scale: 1}}},
+			{ID: "201", Processings: []Processing{{ID: "2", Name: "Soiltemperature100_Avg", Scale: 1}}},
+		},
+		Required: []string{"Temperature_Avg", "Humidity_Avg", "Windspeed_Avg", "Wind Direction_Avg", "Pressure_Avg", "Rainfall_Acc", "Windspeed_Max", "Soiltemperature10_Avg", "Soiltemperature20_Avg", "Soiltemperature50_Avg", "Soiltemperature100_Avg"},
+	}
+	schema.index()
+	return schema
+}
+
+// index builds the measurementID -> processingID -> Processing lookup used
+// by parseRow, defaulting an unset scale to 1 (a no-op transform). The
+// default is written back into s.Measurements, not just the lookup copy,
+// so -print-schema reflects the scale Convert actually applies.
+func (s *Schema) index() {
+	s.processings = make(map[string]map[string]Processing)
+	for i := range s.Measurements {
+		byProcessing := make(map[string]Processing)
+		for j := range s.Measurements[i].Processings {
+			if s.Measurements[i].Processings[j].Scale == 0 {
+				s.Measurements[i].Processings[j].Scale = 1
+			}
+			processing := s.Measurements[i].Processings[j]
+			byProcessing[processing.ID] = processing
+		}
+		s.processings[s.Measurements[i].ID] = byProcessing
+	}
+}
+
+// Lookup returns the Processing configured for a (measurementID,
+// processingID) pair read from a data row.
+func (s *Schema) Lookup(measurementID, processingID string) (Processing, bool) {
+	byProcessing, ok := s.processings[measurementID]
+	if !ok {
+		return Processing{}, false
+	}
+	processing, ok := byProcessing[processingID]
+	return processing, ok
+}
+
+// Columns returns the output column order: output_columns if the mapping
+// file set one, otherwise required.
+func (s *Schema) Columns() []string {
+	if len(s.OutputColumns) > 0 {
+		return s.OutputColumns
+	}
+	return s.Required
+}
+
+// Convert applies the processing's scale/offset to a raw logger value,
+// returning "" for missing ("*") readings.
+func (p Processing) Convert(raw string) (string, error) {
+	if raw == "" || raw == "*" {
+		return "", nil
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid measurement value %q for %s: %v", raw, p.Name, err)
+	}
+	scaled := value*p.Scale + p.Offset
+	return strconv.FormatFloat(scaled, 'f', -1, 64), nil
+}
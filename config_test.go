@@ -0,0 +1,47 @@
+package main
+
+import "testing"
+
+func TestSchemaIndexDefaultsScaleInPlace(t *testing.T) {
+	schema := &Schema{
+		Measurements: []Measurement{
+			{ID: "1", Processings: []Processing{{ID: "2", Name: "Temperature_Avg"}}},
+		},
+	}
+	schema.index()
+
+	// -print-schema marshals Measurements directly, so the default must
+	// land there, not just in the internal lookup map.
+	got := schema.Measurements[0].Processings[0].Scale
+	if got != 1 {
+		t.Fatalf("expected default scale 1 written back to Measurements, got %v", got)
+	}
+
+	processing, ok := schema.Lookup("1", "2")
+	if !ok {
+		t.Fatalf("expected Lookup to find the indexed processing")
+	}
+	if processing.Scale != 1 {
+		t.Fatalf("expected Lookup's Processing.Scale to be 1, got %v", processing.Scale)
+	}
+}
+
+func TestProcessingConvert(t *testing.T) {
+	p := Processing{Name: "Temperature_Avg", Scale: 0.1, Offset: 2}
+
+	value, err := p.Convert("150")
+	if err != nil {
+		t.Fatalf("Convert: %v", err)
+	}
+	if value != "17" {
+		t.Fatalf("expected 150*0.1+2 = 17, got %q", value)
+	}
+
+	if value, err := p.Convert("*"); err != nil || value != "" {
+		t.Fatalf("expected Convert(\"*\") to return empty string with no error, got %q, %v", value, err)
+	}
+
+	if _, err := p.Convert("BADVALUE"); err == nil {
+		t.Fatalf("expected Convert to error on a non-numeric value")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/csv"
+	"os"
+)
+
+// CSVSink writes records as comma-separated values, preserving the column
+// layout the tool has always produced.
+type CSVSink struct {
+	file    *os.File
+	writer  *csv.Writer
+	columns []string
+}
+
+func newCSVSink(outputPath string, appendMode bool, columns []string) (*CSVSink, error) {
+	file, err := openSinkFile(outputPath, appendMode)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVSink{file: file, writer: csv.NewWriter(file), columns: columns}, nil
+}
+
+func (s *CSVSink) WriteHeader(cols []string) error {
+	return s.writer.Write(cols)
+}
+
+func (s *CSVSink) WriteRecord(r Record) error {
+	row := []string{r.StationID, r.Timestamp}
+	for _, param := range s.columns {
+		row = append(row, r.Values[param])
+	}
+	return s.writer.Write(row)
+}
+
+func (s *CSVSink) Close() error {
+	s.writer.Flush()
+	if err := s.writer.Error(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// ParseInputEncoding validates a -input-encoding flag value, returning the
+// decoder to transcode raw line bytes into UTF-8. A nil decoder means the
+// input is already UTF-8 and needs no transcoding.
+func ParseInputEncoding(name string) (*encoding.Decoder, error) {
+	switch name {
+	case "", "utf8":
+		return nil, nil
+	case "latin1":
+		return charmap.ISO8859_1.NewDecoder(), nil
+	case "windows-1252":
+		return charmap.Windows1252.NewDecoder(), nil
+	case "gbk":
+		return simplifiedchinese.GBK.NewDecoder(), nil
+	default:
+		return nil, fmt.Errorf("unknown input encoding %q", name)
+	}
+}
+
+// decodeLine transcodes a raw line (still carrying its original line
+// terminator) into a UTF-8 string. dec is nil when the input is already
+// UTF-8, in which case the bytes are used as-is.
+func decodeLine(raw []byte, dec *encoding.Decoder) (string, error) {
+	if dec == nil {
+		return string(raw), nil
+	}
+	decoded, err := dec.Bytes(raw)
+	if err != nil {
+		return "", fmt.Errorf("cannot decode line: %v", err)
+	}
+	return string(decoded), nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestParseInputEncodingUnknown(t *testing.T) {
+	if _, err := ParseInputEncoding("ebcdic"); err == nil {
+		t.Fatalf("expected an unknown encoding name to error")
+	}
+}
+
+func TestDecodeLineUTF8Passthrough(t *testing.T) {
+	dec, err := ParseInputEncoding("utf8")
+	if err != nil {
+		t.Fatalf("ParseInputEncoding: %v", err)
+	}
+	got, err := decodeLine([]byte("22.5\n"), dec)
+	if err != nil {
+		t.Fatalf("decodeLine: %v", err)
+	}
+	if got != "22.5\n" {
+		t.Fatalf("expected utf8 input to pass through unchanged, got %q", got)
+	}
+}
+
+func TestDecodeLineLatin1(t *testing.T) {
+	dec, err := ParseInputEncoding("latin1")
+	if err != nil {
+		t.Fatalf("ParseInputEncoding: %v", err)
+	}
+
+	// 0xB0 is the Latin-1 degree sign, which is invalid UTF-8 on its own.
+	raw := []byte{'2', '2', '.', 0xB0, '\n'}
+	got, err := decodeLine(raw, dec)
+	if err != nil {
+		t.Fatalf("decodeLine: %v", err)
+	}
+	if got != "22.°\n" {
+		t.Fatalf("expected the degree sign to decode to U+00B0, got %q", got)
+	}
+}
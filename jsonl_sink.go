@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+)
+
+// JSONLSink writes one JSON object per record, newline-delimited, with the
+// measurement values nested under a "values" sub-object so downstream
+// consumers don't need the fixed CSV column order.
+type JSONLSink struct {
+	file    *os.File
+	writer  *bufio.Writer
+	encoder *json.Encoder
+}
+
+type jsonlRecord struct {
+	StationID string                 `json:"station_id"`
+	Timestamp string                 `json:"timestamp"`
+	Values    map[string]interface{} `json:"values"`
+}
+
+func newJSONLSink(outputPath string, appendMode bool) (*JSONLSink, error) {
+	file, err := openSinkFile(outputPath, appendMode)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	return &JSONLSink{file: file, writer: writer, encoder: json.NewEncoder(writer)}, nil
+}
+
+// WriteHeader is a no-op: JSONL records are self-describing.
+func (s *JSONLSink) WriteHeader(cols []string) error {
+	return nil
+}
+
+func (s *JSONLSink) WriteRecord(r Record) error {
+	values := make(map[string]interface{}, len(r.Values))
+	for name := range r.Values {
+		if value, ok := r.Float64(name); ok {
+			values[name] = value
+		}
+	}
+
+	return s.encoder.Encode(jsonlRecord{
+		StationID: r.StationID,
+		Timestamp: r.Timestamp,
+		Values:    values,
+	})
+}
+
+func (s *JSONLSink) Close() error {
+	if err := s.writer.Flush(); err != nil {
+		s.file.Close()
+		return err
+	}
+	return s.file.Close()
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// zeroPad ensures single-digit numbers are padded with a leading zero
+func zeroPad(num string) string {
+	if len(num) == 1 {
+		return "0" + num
+	}
+	return num
+}
+
+// parseRow interprets a single line of input data against cfg's schema and
+// timestamp settings.
+func parseRow(line string, cfg PipelineConfig) (Record, error) {
+	fields := strings.Split(line, ",")
+	if len(fields) < 7 {
+		return Record{}, fmt.Errorf("invalid row: %s", line)
+	}
+
+	stationID := strings.TrimLeft(fields[1], "0") // Remove leading zeros from the station ID
+	hour := zeroPad(fields[2])
+	minute := zeroPad(fields[3])
+	second := zeroPad(fields[4])
+	day := zeroPad(fields[5])
+	month := zeroPad(fields[6])
+	year := fields[7]
+	naiveTimestamp := fmt.Sprintf("%s-%s-%sT%s:%s:%s", year, month, day, hour, minute, second)
+
+	// Parse the timestamp in the logger's own timezone
+	recordTime, err := parseRowTimestamp(naiveTimestamp, cfg.Timestamps)
+	if err != nil {
+		return Record{}, fmt.Errorf("invalid timestamp: %s", naiveTimestamp)
+	}
+
+	// Skip rows older than the cutoff date
+	if recordTime.Before(cfg.Cutoff) {
+		return Record{}, nil
+	}
+
+	values := make(map[string]string)
+	for i := 8; i < len(fields)-1; i += 3 {
+		measurementID := fields[i]
+		processingID := fields[i+1]
+		if i+2 >= len(fields) {
+			continue
+		}
+
+		processing, ok := cfg.Schema.Lookup(measurementID, processingID)
+		if !ok {
+			continue
+		}
+
+		value, err := processing.Convert(fields[i+2])
+		if err != nil {
+			// A glitched sensor reading in one column shouldn't cost every
+			// other valid measurement on the row; drop just this column.
+			fmt.Printf("Skipping %s: %v\n", processing.Name, err)
+			continue
+		}
+		values[processing.Name] = value
+	}
+
+	return Record{
+		StationID:  stationID,
+		Timestamp:  formatTimestamp(recordTime, cfg.Timestamps),
+		Values:     values,
+		occurredAt: recordTime,
+	}, nil
+}
+
+func main() {
+	workers := flag.Int("workers", runtime.NumCPU(), "number of concurrent file-processing workers")
+	format := flag.String("format", "csv", "output format: csv|jsonl|avro|clickhouse")
+	clickhouseAddr := flag.String("clickhouse-addr", "", "ClickHouse HTTP interface base URL (required for -format clickhouse)")
+	clickhouseTable := flag.String("clickhouse-table", "nesacsv", "ClickHouse table to insert into (for -format clickhouse)")
+	statePath := flag.String("state", "", "checkpoint file tracking per-input-file progress (default <output_file>.state)")
+	appendMode := flag.Bool("append", false, "merge into the existing output instead of overwriting it")
+	dedupeWindow := flag.Duration("dedupe-window", 24*time.Hour, "how far back to guard against re-emitting the same (station, timestamp) record")
+	mappingPath := flag.String("mapping", "", "YAML file describing the measurement/processing ID mapping (default: built-in NESA mapping)")
+	printSchema := flag.Bool("print-schema", false, "print the currently loaded mapping schema as YAML and exit")
+	timestampFormat := flag.String("timestamp-format", "native", "timestamp format: native|iso8601|rfc3339|unix|unixmilli")
+	inputTZ := flag.String("input-tz", "UTC", "timezone the raw logger fields are recorded in")
+	outputTZ := flag.String("output-tz", "UTC", "timezone to emit the formatted timestamp in")
+	header := flag.Bool("header", false, "write a column header row on the first file processed")
+	inputEncoding := flag.String("input-encoding", "utf8", "input file encoding: utf8|latin1|windows-1252|gbk")
+	quarantineDir := flag.String("quarantine", "", "directory to write rejected lines to as rotating rejects-YYYYMMDD.csv files (default: log to stdout)")
+	metricsAddr := flag.String("metrics-addr", "", "if set, serve Prometheus metrics on this address (e.g. :9090) for the duration of the run")
+	flag.Parse()
+
+	schema := defaultSchema()
+	if *mappingPath != "" {
+		loaded, err := LoadSchema(*mappingPath)
+		if err != nil {
+			fmt.Printf("Cannot load mapping file: %v\n", err)
+			return
+		}
+		schema = loaded
+	}
+
+	if *printSchema {
+		out, err := yaml.Marshal(schema)
+		if err != nil {
+			fmt.Printf("Cannot print schema: %v\n", err)
+			return
+		}
+		fmt.Print(string(out))
+		return
+	}
+
+	args := flag.Args()
+	if len(args) < 2 {
+		fmt.Println("Usage: nesacsv [-workers N] [-format csv|jsonl|avro|clickhouse] [-mapping file.yaml] [-append] [-header] <input_directory> <output_file> [days]")
+		return
+	}
+
+	inputDir := args[0]
+	outputFile := args[1]
+	days := 14 // Default to 14 days
+	if len(args) > 2 {
+		var err error
+		days, err = strconv.Atoi(args[2])
+		if err != nil {
+			fmt.Printf("Invalid days value: %v\n", err)
+			return
+		}
+	}
+
+	resolvedFormat, err := ParseTimestampFormat(*timestampFormat)
+	if err != nil {
+		fmt.Printf("Invalid -timestamp-format: %v\n", err)
+		return
+	}
+
+	inputLoc, err := time.LoadLocation(*inputTZ)
+	if err != nil {
+		fmt.Printf("Invalid -input-tz: %v\n", err)
+		return
+	}
+	outputLoc, err := time.LoadLocation(*outputTZ)
+	if err != nil {
+		fmt.Printf("Invalid -output-tz: %v\n", err)
+		return
+	}
+
+	decoder, err := ParseInputEncoding(*inputEncoding)
+	if err != nil {
+		fmt.Printf("Invalid -input-encoding: %v\n", err)
+		return
+	}
+
+	var quarantine *QuarantineWriter
+	if *quarantineDir != "" {
+		quarantine, err = NewQuarantineWriter(*quarantineDir)
+		if err != nil {
+			fmt.Printf("Cannot set up quarantine directory: %v\n", err)
+			return
+		}
+		defer quarantine.Close()
+	}
+
+	// Checkpoints only make sense alongside -append: without it, the output
+	// file is truncated and rewritten from scratch on every run, so seeking
+	// into input files past a stale checkpoint would silently drop the
+	// records that built the file being overwritten.
+	var checkpoints *CheckpointStore
+	if *appendMode {
+		if *statePath == "" {
+			*statePath = outputFile + ".state"
+		}
+		checkpoints, err = LoadCheckpointStore(*statePath)
+		if err != nil {
+			fmt.Printf("Cannot load checkpoint file: %v\n", err)
+			return
+		}
+	}
+
+	// A header belongs at the top of a fresh output, not partway through
+	// one an -append run is adding to: only write it if the output doesn't
+	// already have content.
+	writeHeader := *header
+	if *appendMode {
+		if info, statErr := os.Stat(outputFile); statErr == nil && info.Size() > 0 {
+			writeHeader = false
+		}
+	}
+
+	sink, err := NewSink(*format, outputFile, *appendMode, *clickhouseAddr, *clickhouseTable, schema.Columns())
+	if err != nil {
+		fmt.Printf("Cannot create output sink: %v\n", err)
+		return
+	}
+	defer sink.Close()
+
+	var metrics *Metrics
+	if *metricsAddr != "" {
+		metrics = NewMetrics()
+		server := serveMetrics(*metricsAddr)
+		defer shutdownMetrics(server)
+	}
+
+	cfg := PipelineConfig{
+		Workers:      *workers,
+		Cutoff:       time.Now().AddDate(0, 0, -days),
+		Checkpoints:  checkpoints,
+		DedupeWindow: *dedupeWindow,
+		Schema:       schema,
+		Timestamps: TimestampConfig{
+			Format:    resolvedFormat,
+			InputLoc:  inputLoc,
+			OutputLoc: outputLoc,
+		},
+		WriteHeader: writeHeader,
+		Decoder:     decoder,
+		Quarantine:  quarantine,
+		Metrics:     metrics,
+	}
+
+	if err := runPipeline(inputDir, sink, cfg); err != nil {
+		fmt.Printf("Error walking directory: %v\n", err)
+	}
+
+	if checkpoints != nil {
+		if err := checkpoints.Save(); err != nil {
+			fmt.Printf("Cannot save checkpoint file: %v\n", err)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the counters, histograms and gauges that give operators
+// visibility into a running pipeline. A nil *Metrics is safe to use: every
+// method is a no-op, so instrumentation can be threaded through
+// unconditionally regardless of whether -metrics-addr was set.
+type Metrics struct {
+	filesProcessed  prometheus.Counter
+	linesParsed     prometheus.Counter
+	linesSkipped    *prometheus.CounterVec
+	recordsWritten  prometheus.Counter
+	fileDuration    prometheus.Histogram
+	lastRunUnixtime prometheus.Gauge
+	lastRecordTime  *prometheus.GaugeVec
+}
+
+// NewMetrics registers and returns a fresh set of pipeline metrics.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		filesProcessed: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "nesacsv_files_processed_total",
+			Help: "Total number of input files processed.",
+		}),
+		linesParsed: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "nesacsv_lines_parsed_total",
+			Help: "Total number of data lines successfully parsed into records.",
+		}),
+		linesSkipped: promauto.NewCounterVec(prometheus.CounterOpts{
+			Name: "nesacsv_lines_skipped_total",
+			Help: "Total number of input lines skipped, by reason.",
+		}, []string{"reason"}),
+		recordsWritten: promauto.NewCounter(prometheus.CounterOpts{
+			Name: "nesacsv_records_written_total",
+			Help: "Total number of records written to the output sink.",
+		}),
+		fileDuration: promauto.NewHistogram(prometheus.HistogramOpts{
+			Name:    "nesacsv_file_processing_duration_seconds",
+			Help:    "Time taken to process a single input file.",
+			Buckets: prometheus.DefBuckets,
+		}),
+		lastRunUnixtime: promauto.NewGauge(prometheus.GaugeOpts{
+			Name: "nesacsv_last_run_unixtime",
+			Help: "Unix time the pipeline last completed a run.",
+		}),
+		lastRecordTime: promauto.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "nesacsv_last_record_timestamp_seconds",
+			Help: "Unix time of the most recent record seen for a station, so alerting can catch stations that stopped reporting.",
+		}, []string{"station_id"}),
+	}
+}
+
+func (m *Metrics) observeFileDuration(d time.Duration) {
+	if m == nil {
+		return
+	}
+	m.filesProcessed.Inc()
+	m.fileDuration.Observe(d.Seconds())
+}
+
+func (m *Metrics) observeLineParsed(record Record) {
+	if m == nil {
+		return
+	}
+	m.linesParsed.Inc()
+	m.lastRecordTime.WithLabelValues(record.StationID).Set(float64(record.occurredAt.Unix()))
+}
+
+func (m *Metrics) observeLineSkipped(reason string) {
+	if m == nil {
+		return
+	}
+	m.linesSkipped.WithLabelValues(reason).Inc()
+}
+
+func (m *Metrics) observeRecordWritten() {
+	if m == nil {
+		return
+	}
+	m.recordsWritten.Inc()
+}
+
+func (m *Metrics) observeRunComplete(now time.Time) {
+	if m == nil {
+		return
+	}
+	m.lastRunUnixtime.Set(float64(now.Unix()))
+}
+
+// serveMetrics starts an HTTP server exposing /metrics on addr and returns
+// it so the caller can shut it down. It runs until Shutdown is called or it
+// hits a fatal error, at which point it logs and exits the serving
+// goroutine.
+func serveMetrics(addr string) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	server := &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("Metrics server error: %v\n", err)
+		}
+	}()
+
+	return server
+}
+
+// shutdownMetrics gives the metrics server a few seconds to drain in-flight
+// scrapes before returning.
+func shutdownMetrics(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil {
+		fmt.Printf("Error shutting down metrics server: %v\n", err)
+	}
+}
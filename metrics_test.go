@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetricsNilSafe(t *testing.T) {
+	var m *Metrics
+
+	// None of these should panic on a nil receiver, since instrumentation
+	// is threaded through unconditionally whether or not -metrics-addr
+	// was set.
+	m.observeFileDuration(time.Second)
+	m.observeLineParsed(Record{StationID: "1", occurredAt: time.Now()})
+	m.observeLineSkipped("bad_value")
+	m.observeRecordWritten()
+	m.observeRunComplete(time.Now())
+}
+
+func TestNewMetricsWiresCollectors(t *testing.T) {
+	m := NewMetrics()
+
+	m.observeFileDuration(time.Second)
+	m.observeLineParsed(Record{StationID: "1", occurredAt: time.Unix(1704164645, 0)})
+	m.observeLineSkipped("bad_value")
+	m.observeRecordWritten()
+	m.observeRunComplete(time.Unix(1704164645, 0))
+
+	if got := testutil.ToFloat64(m.filesProcessed); got != 1 {
+		t.Errorf("filesProcessed: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.linesParsed); got != 1 {
+		t.Errorf("linesParsed: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.linesSkipped.WithLabelValues("bad_value")); got != 1 {
+		t.Errorf("linesSkipped{reason=bad_value}: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.recordsWritten); got != 1 {
+		t.Errorf("recordsWritten: got %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.lastRunUnixtime); got != 1704164645 {
+		t.Errorf("lastRunUnixtime: got %v, want 1704164645", got)
+	}
+	if got := testutil.ToFloat64(m.lastRecordTime.WithLabelValues("1")); got != 1704164645 {
+		t.Errorf("lastRecordTime{station_id=1}: got %v, want 1704164645", got)
+	}
+}
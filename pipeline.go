@@ -0,0 +1,292 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/text/encoding"
+)
+
+// PipelineConfig bundles everything a single runPipeline invocation needs
+// beyond the input directory and sink: how many workers to use, which rows
+// to keep, how to checkpoint and dedupe, how to interpret/format
+// timestamps, and how to decode and quarantine raw input.
+type PipelineConfig struct {
+	Workers      int
+	Cutoff       time.Time
+	Checkpoints  *CheckpointStore
+	DedupeWindow time.Duration
+	Schema       *Schema
+	Timestamps   TimestampConfig
+	WriteHeader  bool
+	Decoder      *encoding.Decoder
+	Quarantine   *QuarantineWriter
+	Metrics      *Metrics
+}
+
+// runPipeline walks inputDir for .txt files and fans the work out across
+// cfg.Workers concurrent goroutines. A single collector goroutine gathers
+// the results, sorts them by (occurredAt, StationID) so output is
+// deterministic regardless of worker scheduling, dedupes records within
+// cfg.DedupeWindow of now, and is the only goroutine that ever touches
+// sink, so no synchronization is needed around it. cfg.Checkpoints, if
+// non-nil, is updated with each file's new FileState so the next run can
+// resume from it.
+func runPipeline(inputDir string, sink Sink, cfg PipelineConfig) error {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	filesChan := make(chan string)
+	recordsChan := make(chan []Record)
+	collectorDone := make(chan struct{})
+
+	go func() {
+		defer close(collectorDone)
+
+		var all []Record
+		for records := range recordsChan {
+			all = append(all, records...)
+		}
+
+		sort.Slice(all, func(i, j int) bool {
+			if !all[i].occurredAt.Equal(all[j].occurredAt) {
+				return all[i].occurredAt.Before(all[j].occurredAt)
+			}
+			return all[i].StationID < all[j].StationID
+		})
+
+		if cfg.WriteHeader {
+			cols := append([]string{"station_id", "timestamp"}, cfg.Schema.Columns()...)
+			if err := sink.WriteHeader(cols); err != nil {
+				fmt.Printf("Error writing header: %v\n", err)
+			}
+		}
+
+		for _, record := range dedupeRecords(all, cfg.DedupeWindow, cfg.Checkpoints) {
+			if err := sink.WriteRecord(record); err != nil {
+				fmt.Printf("Error writing record for station %s: %v\n", record.StationID, err)
+				continue
+			}
+			cfg.Metrics.observeRecordWritten()
+		}
+
+		if cfg.Checkpoints != nil {
+			cfg.Checkpoints.PruneEmitted(cfg.DedupeWindow)
+		}
+
+		cfg.Metrics.observeRunComplete(time.Now())
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range filesChan {
+				fmt.Printf("Processing file: %s\n", path)
+
+				var prevState FileState
+				var hasPrevState bool
+				if cfg.Checkpoints != nil {
+					prevState, hasPrevState = cfg.Checkpoints.Get(path)
+				}
+
+				records, newState, err := processFile(path, prevState, hasPrevState, cfg)
+				if err != nil {
+					fmt.Printf("Error processing file %s: %v\n", path, err)
+					continue
+				}
+
+				if cfg.Checkpoints != nil {
+					cfg.Checkpoints.Set(path, newState)
+				}
+
+				recordsChan <- records
+			}
+		}()
+	}
+
+	walkErr := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("cannot access %s: %v", path, err)
+		}
+		if !info.IsDir() && strings.HasSuffix(info.Name(), ".txt") {
+			filesChan <- path
+		}
+		return nil
+	})
+	close(filesChan)
+
+	wg.Wait()
+	close(recordsChan)
+	<-collectorDone
+
+	return walkErr
+}
+
+// dedupeKey identifies a record for overlap detection across incremental
+// runs of the same file.
+type dedupeKey struct {
+	StationID string
+	Timestamp string
+}
+
+// String renders key for use as a map key in the JSON-persisted
+// CheckpointStore.emitted, where object keys must be strings.
+func (k dedupeKey) String() string {
+	return k.StationID + "\x00" + k.Timestamp
+}
+
+// dedupeRecords drops records that share a (StationID, Timestamp) with one
+// already seen in this run or, if checkpoints is non-nil, emitted by a
+// previous run, but only considers records within window of now:
+// incremental re-reads can only overlap near the tail of a file, so older
+// records are never worth the memory of tracking. Surviving records within
+// the window are recorded in checkpoints so the next run can see them too.
+func dedupeRecords(records []Record, window time.Duration, checkpoints *CheckpointStore) []Record {
+	if window <= 0 {
+		return records
+	}
+
+	seen := make(map[dedupeKey]struct{})
+	horizon := time.Now().Add(-window)
+	now := time.Now()
+
+	out := make([]Record, 0, len(records))
+	for _, record := range records {
+		if record.occurredAt.Before(horizon) {
+			out = append(out, record)
+			continue
+		}
+
+		key := dedupeKey{record.StationID, record.Timestamp}
+		if _, dup := seen[key]; dup {
+			continue
+		}
+		if checkpoints != nil && checkpoints.WasRecentlyEmitted(key, window) {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, record)
+
+		if checkpoints != nil {
+			checkpoints.MarkEmitted(key, now)
+		}
+	}
+	return out
+}
+
+// processFile parses every "S," data line appended to filePath since
+// prevState was recorded, skipping rows older than cfg.Cutoff. If
+// hasPrevState is false, the header hash changed (log rotation), or the
+// file shrank (truncation), it reprocesses from the start. It returns the
+// records found and the FileState to checkpoint for the next run.
+func processFile(filePath string, prevState FileState, hasPrevState bool, cfg PipelineConfig) ([]Record, FileState, error) {
+	start := time.Now()
+	defer func() { cfg.Metrics.observeFileDuration(time.Since(start)) }()
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, FileState{}, fmt.Errorf("cannot open file %s: %v", filePath, err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, FileState{}, fmt.Errorf("cannot stat file %s: %v", filePath, err)
+	}
+
+	headerHash, err := hashFileHeader(file)
+	if err != nil {
+		return nil, FileState{}, fmt.Errorf("cannot hash file %s: %v", filePath, err)
+	}
+
+	startOffset := int64(0)
+	if hasPrevState && info.Size() >= prevState.Size && headerMatches(prevState, headerHash) {
+		startOffset = prevState.Offset
+	}
+
+	if _, err := file.Seek(startOffset, io.SeekStart); err != nil {
+		return nil, FileState{}, fmt.Errorf("cannot seek file %s: %v", filePath, err)
+	}
+
+	var records []Record
+	offset := startOffset
+	lineNum := 0
+	reader := bufio.NewReader(file)
+	for {
+		raw, err := reader.ReadString('\n')
+		complete := strings.HasSuffix(raw, "\n")
+		if complete {
+			lineNum++
+			line, decErr := decodeLine([]byte(raw), cfg.Decoder)
+			if decErr != nil {
+				quarantineLine(cfg, filePath, lineNum, raw, decErr)
+				cfg.Metrics.observeLineSkipped("decode-error")
+			} else if record, ok, parseErr := parseLine(strings.TrimRight(line, "\r\n"), cfg); parseErr != nil {
+				quarantineLine(cfg, filePath, lineNum, line, parseErr)
+				cfg.Metrics.observeLineSkipped("parse-error")
+			} else if ok {
+				records = append(records, record)
+				cfg.Metrics.observeLineParsed(record)
+			} else {
+				cfg.Metrics.observeLineSkipped("filtered")
+			}
+			offset += int64(len(raw))
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, FileState{}, fmt.Errorf("error reading file %s: %v", filePath, err)
+		}
+	}
+
+	newState := FileState{Offset: offset, Size: info.Size(), ModTime: info.ModTime(), HeaderHash: headerHash}
+	return records, newState, nil
+}
+
+// parseLine parses a single scanned line, reporting whether it produced a
+// record worth keeping (an "S," row, newer than cfg.Cutoff). A non-nil
+// error means the line looked like a data row but failed to parse; it is
+// distinct from ok==false, which also covers intentional skips (non-data
+// lines, rows older than cfg.Cutoff).
+func parseLine(line string, cfg PipelineConfig) (Record, bool, error) {
+	if !strings.HasPrefix(line, "S,") {
+		return Record{}, false, nil
+	}
+
+	record, err := parseRow(line, cfg)
+	if err != nil {
+		return Record{}, false, err
+	}
+
+	if record.occurredAt.IsZero() {
+		return Record{}, false, nil // Skip if the record is too old
+	}
+
+	return record, true, nil
+}
+
+// quarantineLine records a rejected line to cfg.Quarantine if configured,
+// falling back to stdout otherwise so operators without -quarantine set
+// still see what was skipped.
+func quarantineLine(cfg PipelineConfig, filePath string, lineNum int, line string, cause error) {
+	if cfg.Quarantine == nil {
+		fmt.Printf("Skipping line %d of %s due to error: %v\n", lineNum, filePath, cause)
+		return
+	}
+	if err := cfg.Quarantine.Reject(filePath, lineNum, line, cause); err != nil {
+		fmt.Printf("Error quarantining line %d of %s: %v\n", lineNum, filePath, err)
+	}
+}
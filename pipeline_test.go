@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeSyntheticCorpus creates n station files, each with a handful of "S,"
+// data lines, under dir and returns dir.
+func writeSyntheticCorpus(tb testing.TB, dir string, n int) string {
+	tb.Helper()
+
+	for i := 0; i < n; i++ {
+		path := filepath.Join(dir, fmt.Sprintf("station-%04d.txt", i))
+		f, err := os.Create(path)
+		if err != nil {
+			tb.Fatalf("cannot create synthetic file %s: %v", path, err)
+		}
+
+		for line := 0; line < 5; line++ {
+			fmt.Fprintf(f, "S,%04d,%d,%d,%d,%d,%d,2024,1,2,21.%d,9,7,%d.0\n",
+				i, 10, 0, line, 1, 1, line, line)
+		}
+
+		if err := f.Close(); err != nil {
+			tb.Fatalf("cannot close synthetic file %s: %v", path, err)
+		}
+	}
+
+	return dir
+}
+
+func BenchmarkRunPipeline1000Files(b *testing.B) {
+	dir := writeSyntheticCorpus(b, b.TempDir(), 1000)
+	schema := defaultSchema()
+	cfg := PipelineConfig{
+		Workers:      8,
+		Cutoff:       time.Now().AddDate(-10, 0, 0),
+		DedupeWindow: 24 * time.Hour,
+		Schema:       schema,
+		Timestamps:   TimestampConfig{Format: "native", InputLoc: time.UTC, OutputLoc: time.UTC},
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		outPath := filepath.Join(b.TempDir(), "out.csv")
+		sink, err := newCSVSink(outPath, false, schema.Columns())
+		if err != nil {
+			b.Fatalf("cannot create output sink: %v", err)
+		}
+
+		checkpoints, err := LoadCheckpointStore(outPath + ".state")
+		if err != nil {
+			b.Fatalf("cannot load checkpoint store: %v", err)
+		}
+		cfg.Checkpoints = checkpoints
+
+		if err := runPipeline(dir, sink, cfg); err != nil {
+			b.Fatalf("runPipeline failed: %v", err)
+		}
+		sink.Close()
+	}
+}
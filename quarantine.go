@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// QuarantineWriter records lines that failed to parse so operators have an
+// audit trail instead of scrollback grepping. Rejects are appended to
+// rejects-YYYYMMDD.csv under dir, rotating to a new file as the date
+// changes.
+type QuarantineWriter struct {
+	dir string
+
+	mu     sync.Mutex
+	day    string
+	file   *os.File
+	writer *csv.Writer
+}
+
+// NewQuarantineWriter creates dir if needed and returns a writer ready to
+// accept rejected lines.
+func NewQuarantineWriter(dir string) (*QuarantineWriter, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create quarantine directory %s: %v", dir, err)
+	}
+	return &QuarantineWriter{dir: dir}, nil
+}
+
+// Reject appends one rejected line to today's rejects file, recording where
+// it came from and why it was rejected.
+func (q *QuarantineWriter) Reject(sourcePath string, lineNum int, line string, cause error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	day := time.Now().Format("20060102")
+	if day != q.day {
+		if err := q.rotate(day); err != nil {
+			return err
+		}
+	}
+
+	if err := q.writer.Write([]string{sourcePath, fmt.Sprintf("%d", lineNum), cause.Error(), line}); err != nil {
+		return fmt.Errorf("cannot write quarantine record: %v", err)
+	}
+	q.writer.Flush()
+	return q.writer.Error()
+}
+
+// rotate closes the current rejects file, if any, and opens (or creates)
+// the one for day.
+func (q *QuarantineWriter) rotate(day string) error {
+	if q.file != nil {
+		q.writer.Flush()
+		q.file.Close()
+	}
+
+	path := filepath.Join(q.dir, fmt.Sprintf("rejects-%s.csv", day))
+	writeHeader := false
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		writeHeader = true
+	}
+
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("cannot open quarantine file %s: %v", path, err)
+	}
+
+	q.day = day
+	q.file = file
+	q.writer = csv.NewWriter(file)
+
+	if writeHeader {
+		if err := q.writer.Write([]string{"source_path", "line", "error", "raw_line"}); err != nil {
+			return fmt.Errorf("cannot write quarantine header: %v", err)
+		}
+		q.writer.Flush()
+	}
+	return nil
+}
+
+// Close flushes and closes the currently open rejects file, if any.
+func (q *QuarantineWriter) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.file == nil {
+		return nil
+	}
+	q.writer.Flush()
+	err := q.file.Close()
+	q.file = nil
+	return err
+}
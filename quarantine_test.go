@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestQuarantineWriterReject(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQuarantineWriter(dir)
+	if err != nil {
+		t.Fatalf("NewQuarantineWriter: %v", err)
+	}
+	defer q.Close()
+
+	if err := q.Reject("/input/station-1.txt", 3, "S,garbage", errors.New("boom")); err != nil {
+		t.Fatalf("Reject: %v", err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected exactly one rejects file, got %d", len(entries))
+	}
+	if !strings.HasPrefix(entries[0].Name(), "rejects-") {
+		t.Fatalf("expected a rejects-YYYYMMDD.csv file, got %q", entries[0].Name())
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	contents := string(data)
+	for _, want := range []string{"/input/station-1.txt", "3", "boom", "S,garbage"} {
+		if !strings.Contains(contents, want) {
+			t.Errorf("expected rejects file to contain %q, got:\n%s", want, contents)
+		}
+	}
+}
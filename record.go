@@ -0,0 +1,41 @@
+package main
+
+import (
+	"strconv"
+	"time"
+)
+
+// Record represents a single data entry
+type Record struct {
+	StationID string
+	Timestamp string
+	Values    map[string]string
+
+	// occurredAt is the absolute instant Timestamp was formatted from. It
+	// is kept alongside the formatted string so sorting and dedupe don't
+	// have to re-parse Timestamp, whose layout depends on -timestamp-format.
+	occurredAt time.Time
+}
+
+// Float64 returns the value named name parsed as a float64. The second
+// return value reports whether the field was present and parsed
+// successfully, so non-CSV sinks can emit native numeric types instead of
+// being forced through strings.
+func (r Record) Float64(name string) (float64, bool) {
+	raw, ok := r.Values[name]
+	if !ok || raw == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, false
+	}
+	return value, true
+}
+
+// IsMissing reports whether name was absent or blank in the row, i.e. the
+// logger recorded it as "*" or never sent it at all.
+func (r Record) IsMissing(name string) bool {
+	raw, ok := r.Values[name]
+	return !ok || raw == ""
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// Sink is the write side of the pipeline. Each output format (CSV, JSONL,
+// Avro, ClickHouse, ...) implements it so the collector in runPipeline does
+// not need to know how records are actually persisted.
+type Sink interface {
+	WriteHeader(cols []string) error
+	WriteRecord(r Record) error
+	Close() error
+}
+
+// NewSink constructs the Sink for the given -format flag value. outputPath
+// is where file-based sinks write; appendMode, when true, merges into an
+// existing file rather than truncating it (used for incremental runs);
+// clickhouseAddr and clickhouseTable configure the clickhouse sink's HTTP
+// endpoint; columns is the output column order from the loaded Schema.
+func NewSink(format, outputPath string, appendMode bool, clickhouseAddr, clickhouseTable string, columns []string) (Sink, error) {
+	switch format {
+	case "", "csv":
+		return newCSVSink(outputPath, appendMode, columns)
+	case "jsonl":
+		return newJSONLSink(outputPath, appendMode)
+	case "avro":
+		return newAvroSink(outputPath, appendMode, columns)
+	case "clickhouse":
+		return newClickHouseSink(clickhouseAddr, clickhouseTable)
+	default:
+		return nil, fmt.Errorf("unknown output format %q", format)
+	}
+}
+
+// openSinkFile opens outputPath for a file-based sink, appending to it
+// in incremental mode instead of truncating it.
+func openSinkFile(outputPath string, appendMode bool) (*os.File, error) {
+	if appendMode {
+		return os.OpenFile(outputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	}
+	return os.Create(outputPath)
+}
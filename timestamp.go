@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// nativeTimestampLayout is the format the tool has always emitted: a naive
+// "YYYY-MM-DDTHH:MM:SS" string with no timezone.
+const nativeTimestampLayout = "2006-01-02T15:04:05"
+
+// TimestampConfig controls how row timestamps are interpreted and emitted:
+// InputLoc is the timezone the logger recorded fields in, OutputLoc is the
+// zone the formatted string is expressed in, and Format picks the layout.
+type TimestampConfig struct {
+	Format    string
+	InputLoc  *time.Location
+	OutputLoc *time.Location
+}
+
+// ParseTimestampFormat validates a -timestamp-format flag value.
+func ParseTimestampFormat(format string) (string, error) {
+	switch format {
+	case "", "native", "iso8601", "rfc3339", "unix", "unixmilli":
+		if format == "" {
+			return "native", nil
+		}
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown timestamp format %q", format)
+	}
+}
+
+// parseRowTimestamp interprets the logger's naive date/time fields in
+// cfg.InputLoc, returning the absolute instant they refer to.
+func parseRowTimestamp(layout string, cfg TimestampConfig) (time.Time, error) {
+	return time.ParseInLocation(nativeTimestampLayout, layout, cfg.InputLoc)
+}
+
+// formatTimestamp renders t in cfg.OutputLoc using cfg.Format.
+func formatTimestamp(t time.Time, cfg TimestampConfig) string {
+	t = t.In(cfg.OutputLoc)
+	switch cfg.Format {
+	case "iso8601":
+		return t.Format("2006-01-02T15:04:05-0700")
+	case "rfc3339":
+		return t.Format(time.RFC3339)
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10)
+	case "unixmilli":
+		return strconv.FormatInt(t.UnixMilli(), 10)
+	default: // "native"
+		return t.Format(nativeTimestampLayout)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestampFormat(t *testing.T) {
+	if format, err := ParseTimestampFormat(""); err != nil || format != "native" {
+		t.Fatalf("expected empty format to default to native, got %q, %v", format, err)
+	}
+	if _, err := ParseTimestampFormat("bogus"); err == nil {
+		t.Fatalf("expected an unknown format to error")
+	}
+}
+
+func TestParseAndFormatTimestampRoundTrip(t *testing.T) {
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	cfg := TimestampConfig{Format: "rfc3339", InputLoc: sydney, OutputLoc: time.UTC}
+
+	// A logger field of 10:00 in Sydney (AEDT, UTC+11 in January) must be
+	// reported as 23:00 the previous day in UTC.
+	recordTime, err := parseRowTimestamp("2024-01-02T10:00:00", cfg)
+	if err != nil {
+		t.Fatalf("parseRowTimestamp: %v", err)
+	}
+
+	got := formatTimestamp(recordTime, cfg)
+	want := "2024-01-01T23:00:00Z"
+	if got != want {
+		t.Fatalf("formatTimestamp: got %q, want %q", got, want)
+	}
+}
+
+func TestFormatTimestampVariants(t *testing.T) {
+	instant := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := map[string]string{
+		"native":  "2024-01-02T03:04:05",
+		"rfc3339": "2024-01-02T03:04:05Z",
+		"unix":    "1704164645",
+	}
+	for format, want := range cases {
+		got := formatTimestamp(instant, TimestampConfig{Format: format, OutputLoc: time.UTC})
+		if got != want {
+			t.Errorf("formatTimestamp(%q): got %q, want %q", format, got, want)
+		}
+	}
+}